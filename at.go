@@ -0,0 +1,317 @@
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseAtOption configures ParseAt and ParseSequence.
+type ParseAtOption func(*atOptions)
+
+type atOptions struct {
+	roundUp  bool
+	location *time.Location
+}
+
+func newAtOptions(opts []ParseAtOption) *atOptions {
+	o := &atOptions{location: time.UTC}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// RoundUp makes a "/unit" rounding term in the expression round up to the
+// end of the unit instead of down to its start.
+func RoundUp() ParseAtOption {
+	return func(o *atOptions) { o.roundUp = true }
+}
+
+// WithLocation sets the Location a partial-ISO anchor and any "/unit"
+// rounding term are resolved in. It defaults to UTC.
+func WithLocation(loc *time.Location) ParseAtOption {
+	return func(o *atOptions) { o.location = loc }
+}
+
+// atSchedule is a one-shot Schedule that fires exactly once, at instant.
+type atSchedule struct {
+	instant time.Time
+}
+
+// ParseAt parses a go-datemath-style anchor-and-offset expression, such as
+// "now+1h" or "2014-11-18||+2y/d", against now and returns a Schedule that
+// fires exactly once at the resolved instant. The anchor is either "now"
+// or a full or partial ISO-8601 timestamp ("2014", "2014-05-30T20:21",
+// ...), with missing components filled in at their minimum. It's followed
+// by zero or more terms, each either a signed calendar offset,
+// "{+|-}{n}{y|M|w|d|h|m|s}", or a rounding term, "/{y|M|w|d|h|m|s}", which
+// snaps to the start of that unit (or its end, with RoundUp). Terms are
+// applied left to right, so rounding can be followed by more arithmetic,
+// as in "now-1d/d+8h" (yesterday at 08:00). An ISO anchor is separated
+// from its terms by "||"; "now" needs no separator.
+func ParseAt(expr string, now time.Time, opts ...ParseAtOption) (Schedule, error) {
+	instant, err := resolveAt(expr, now, newAtOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %s", expr, err)
+	}
+	return &atSchedule{instant: instant}, nil
+}
+
+// ParseSequence parses a ";"-separated list of ParseAt expressions,
+// evaluated against the same now, into a Schedule that fires once at each
+// resolved instant, in chronological order.
+func ParseSequence(expr string, now time.Time, opts ...ParseAtOption) (Schedule, error) {
+	o := newAtOptions(opts)
+
+	parts := strings.Split(expr, ";")
+	instants := make([]time.Time, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		instant, err := resolveAt(part, now, o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %s", part, err)
+		}
+		instants = append(instants, instant)
+	}
+	if len(instants) == 0 {
+		return nil, fmt.Errorf("empty sequence: %q", expr)
+	}
+	sort.Slice(instants, func(i, j int) bool { return instants[i].Before(instants[j]) })
+
+	return &sequenceSchedule{instants: instants}, nil
+}
+
+// Next returns instant if t is before it, and the zero time otherwise.
+func (a *atSchedule) Next(t time.Time) time.Time {
+	if t.Before(a.instant) {
+		return a.instant
+	}
+	return time.Time{}
+}
+
+// Prev returns instant once t has reached it, and the zero time otherwise.
+func (a *atSchedule) Prev(t time.Time) time.Time {
+	if !t.Before(a.instant) {
+		return a.instant
+	}
+	return time.Time{}
+}
+
+// sequenceSchedule is a one-shot Schedule per instant in instants, which
+// must be sorted ascending.
+type sequenceSchedule struct {
+	instants []time.Time
+}
+
+func (s *sequenceSchedule) Next(t time.Time) time.Time {
+	for _, instant := range s.instants {
+		if t.Before(instant) {
+			return instant
+		}
+	}
+	return time.Time{}
+}
+
+func (s *sequenceSchedule) Prev(t time.Time) time.Time {
+	for i := len(s.instants) - 1; i >= 0; i-- {
+		if !t.Before(s.instants[i]) {
+			return s.instants[i]
+		}
+	}
+	return time.Time{}
+}
+
+// resolveAt resolves a single anchor-and-offset expression to an instant.
+func resolveAt(expr string, now time.Time, o *atOptions) (time.Time, error) {
+	anchorField, opsField := splitAnchor(strings.TrimSpace(expr))
+
+	anchor, err := parseAnchor(anchorField, now, o.location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ops, err := parseDateMathOps(opsField)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return applyDateMathOps(anchor, ops, o), nil
+}
+
+// splitAnchor splits expr into its anchor and the date-math terms that
+// follow it. "now" needs no "||" separator; any other anchor does.
+func splitAnchor(expr string) (anchor, ops string) {
+	if expr == "now" || strings.HasPrefix(expr, "now+") || strings.HasPrefix(expr, "now-") || strings.HasPrefix(expr, "now/") {
+		return "now", expr[len("now"):]
+	}
+	if idx := strings.Index(expr, "||"); idx >= 0 {
+		return expr[:idx], expr[idx+2:]
+	}
+	return expr, ""
+}
+
+func parseAnchor(field string, now time.Time, loc *time.Location) (time.Time, error) {
+	if field == "now" {
+		return now, nil
+	}
+	t, err := parsePartialISO(field, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid anchor %q: %s", field, err)
+	}
+	return t, nil
+}
+
+// partialISOPattern matches a full or partial ISO-8601 timestamp with no
+// zone offset: a 4-digit year, with month, day, hour, minute and second
+// each optional but only if everything before it was given too.
+var partialISOPattern = regexp.MustCompile(
+	`^(\d{4})(?:-(\d{2})(?:-(\d{2})(?:[T ](\d{2})(?::(\d{2})(?::(\d{2}))?)?)?)?)?$`)
+
+// parsePartialISO parses a full RFC3339 timestamp, or a partial ISO-8601
+// timestamp with the missing components filled in at their minimum
+// ("2014" -> 2014-01-01T00:00:00), in loc.
+func parsePartialISO(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	m := partialISOPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("expected an ISO-8601 timestamp, found %q", s)
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, day, hour, minute, second := 1, 1, 0, 0, 0
+	if m[2] != "" {
+		month, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+	}
+	if m[4] != "" {
+		hour, _ = strconv.Atoi(m[4])
+	}
+	if m[5] != "" {
+		minute, _ = strconv.Atoi(m[5])
+	}
+	if m[6] != "" {
+		second, _ = strconv.Atoi(m[6])
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, loc), nil
+}
+
+// dateMathUnits are the recognized calendar units, in both their
+// arithmetic ("y", "M") and shared rounding form.
+const dateMathUnits = "yMwdhms"
+
+// dateMathOp is one "{+|-}{n}{unit}" arithmetic term or "/{unit}" rounding
+// term of a date-math expression.
+type dateMathOp struct {
+	kind rune // '+', '-' or '/'
+	n    int
+	unit byte
+}
+
+var dateMathTermPattern = regexp.MustCompile(`^([+-])(\d+)([` + dateMathUnits + `])`)
+
+// parseDateMathOps parses the ops field of a date-math expression (the
+// part after the anchor) into a sequence of terms.
+func parseDateMathOps(s string) ([]dateMathOp, error) {
+	var ops []dateMathOp
+	for len(s) > 0 {
+		switch s[0] {
+		case '+', '-':
+			m := dateMathTermPattern.FindStringSubmatch(s)
+			if m == nil {
+				return nil, fmt.Errorf("invalid date-math term in %q", s)
+			}
+			n, _ := strconv.Atoi(m[2])
+			ops = append(ops, dateMathOp{kind: rune(m[1][0]), n: n, unit: m[3][0]})
+			s = s[len(m[0]):]
+		case '/':
+			if len(s) < 2 || !strings.ContainsRune(dateMathUnits, rune(s[1])) {
+				return nil, fmt.Errorf("invalid rounding term in %q", s)
+			}
+			ops = append(ops, dateMathOp{kind: '/', unit: s[1]})
+			s = s[2:]
+		default:
+			return nil, fmt.Errorf("unexpected character %q in date-math expression", s[0:1])
+		}
+	}
+	return ops, nil
+}
+
+// applyDateMathOps applies ops to anchor in order, resolving rounding
+// terms in o.location according to o.roundUp.
+func applyDateMathOps(anchor time.Time, ops []dateMathOp, o *atOptions) time.Time {
+	t := anchor.In(o.location)
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			t = addDateMathUnit(t, op.n, op.unit)
+		case '-':
+			t = addDateMathUnit(t, -op.n, op.unit)
+		case '/':
+			if o.roundUp {
+				t = dateMathCeil(t, op.unit, o.location)
+			} else {
+				t = dateMathFloor(t, op.unit, o.location)
+			}
+		}
+	}
+	return t
+}
+
+// addDateMathUnit adds n units to t. Calendar units (y, M, w, d) go
+// through AddDate for calendar-correct, DST-correct results; clock units
+// (h, m, s) are plain durations.
+func addDateMathUnit(t time.Time, n int, unit byte) time.Time {
+	switch unit {
+	case 'y':
+		return t.AddDate(n, 0, 0)
+	case 'M':
+		return t.AddDate(0, n, 0)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour)
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute)
+	default: // 's'
+		return t.Add(time.Duration(n) * time.Second)
+	}
+}
+
+// dateMathFloor rounds t down to the start of unit, in loc.
+func dateMathFloor(t time.Time, unit byte, loc *time.Location) time.Time {
+	lt := t.In(loc)
+	switch unit {
+	case 'y':
+		return time.Date(lt.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	case 'M':
+		return time.Date(lt.Year(), lt.Month(), 1, 0, 0, 0, 0, loc)
+	case 'w':
+		monday := time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+		daysSinceMonday := int(lt.Weekday()+6) % 7
+		return monday.AddDate(0, 0, -daysSinceMonday)
+	case 'd':
+		return time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+	case 'h':
+		return time.Date(lt.Year(), lt.Month(), lt.Day(), lt.Hour(), 0, 0, 0, loc)
+	case 'm':
+		return time.Date(lt.Year(), lt.Month(), lt.Day(), lt.Hour(), lt.Minute(), 0, 0, loc)
+	default: // 's'
+		return time.Date(lt.Year(), lt.Month(), lt.Day(), lt.Hour(), lt.Minute(), lt.Second(), 0, loc)
+	}
+}
+
+// dateMathCeil rounds t up to the end of unit, in loc.
+func dateMathCeil(t time.Time, unit byte, loc *time.Location) time.Time {
+	start := dateMathFloor(t, unit, loc)
+	return addDateMathUnit(start, 1, unit).Add(-time.Nanosecond)
+}
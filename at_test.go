@@ -0,0 +1,133 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAt(t *testing.T) {
+	now := time.Date(2014, time.November, 18, 12, 0, 0, 0, time.UTC)
+
+	runs := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"now+1h", now.Add(time.Hour)},
+		{"now-1d/d+8h", time.Date(2014, time.November, 17, 8, 0, 0, 0, time.UTC)},
+		{"2014-11-18||+2y/d", time.Date(2016, time.November, 18, 0, 0, 0, 0, time.UTC)},
+		{"2014", time.Date(2014, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"2014-05", time.Date(2014, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		{"2014-05-30T20:21", time.Date(2014, time.May, 30, 20, 21, 0, 0, time.UTC)},
+		// Jan 31 + 1 month must land in March, not silently roll past it.
+		{"2014-01-31||+1M", time.Date(2014, time.March, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, r := range runs {
+		sched, err := ParseAt(r.expr, now)
+		if err != nil {
+			t.Errorf("%q: %v", r.expr, err)
+			continue
+		}
+		at := sched.(*atSchedule).instant
+		if !at.Equal(r.expected) {
+			t.Errorf("%q: (expected) %v != %v (actual)", r.expr, r.expected, at)
+		}
+	}
+}
+
+func TestParseAtRoundUp(t *testing.T) {
+	now := time.Date(2014, time.November, 18, 12, 30, 0, 0, time.UTC)
+
+	sched, err := ParseAt("now/d", now, RoundUp())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2014, time.November, 18, 23, 59, 59, 999999999, time.UTC)
+	if at := sched.(*atSchedule).instant; !at.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, at)
+	}
+}
+
+func TestParseAtLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2014, time.November, 18, 12, 0, 0, 0, time.UTC)
+
+	sched, err := ParseAt("now/d", now, WithLocation(loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2014, time.November, 18, 0, 0, 0, 0, loc)
+	if at := sched.(*atSchedule).instant; !at.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, at)
+	}
+}
+
+func TestParseAtSchedule(t *testing.T) {
+	now := time.Date(2014, time.November, 18, 12, 0, 0, 0, time.UTC)
+	sched, err := ParseAt("now+1h", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instant := now.Add(time.Hour)
+
+	if got := sched.Next(now); !got.Equal(instant) {
+		t.Errorf("Next(now): (expected) %v != %v (actual)", instant, got)
+	}
+	if got := sched.Next(instant); !got.IsZero() {
+		t.Errorf("Next(instant): expected zero time, got %v", got)
+	}
+	if got := sched.Prev(now); !got.IsZero() {
+		t.Errorf("Prev(now): expected zero time, got %v", got)
+	}
+	if got := sched.Prev(instant); !got.Equal(instant) {
+		t.Errorf("Prev(instant): (expected) %v != %v (actual)", instant, got)
+	}
+}
+
+func TestParseAtErrors(t *testing.T) {
+	now := time.Now()
+	invalidExprs := []string{
+		"",
+		"2014-1", // month must be two digits
+		"banana",
+		"now+1x",
+		"now/x",
+		"2014-11-18+1d", // missing the || separator
+	}
+	for _, expr := range invalidExprs {
+		if _, err := ParseAt(expr, now); err == nil {
+			t.Errorf("expected an error parsing %q", expr)
+		}
+	}
+}
+
+func TestParseSequence(t *testing.T) {
+	now := time.Date(2014, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	sched, err := ParseSequence("now+2h; now+1h; now+3h", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := sched.Next(now)
+	if expected := now.Add(time.Hour); !first.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, first)
+	}
+	second := sched.Next(first)
+	if expected := now.Add(2 * time.Hour); !second.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, second)
+	}
+	third := sched.Next(second)
+	if expected := now.Add(3 * time.Hour); !third.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, third)
+	}
+	if got := sched.Next(third); !got.IsZero() {
+		t.Errorf("expected zero time, got %v", got)
+	}
+
+	if got := sched.Prev(now.Add(90 * time.Minute)); !got.Equal(first) {
+		t.Errorf("(expected) %v != %v (actual)", first, got)
+	}
+}
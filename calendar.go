@@ -0,0 +1,418 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CalendarSchedule is a Schedule parsed from a systemd.time(7) "OnCalendar"
+// expression, e.g. "Mon..Fri *-*-* 10:00" or "weekly". Second, Minute, Hour,
+// Dom, Month and Weekday use the same bitmask representation as
+// SpecSchedule. Unlike the cron dialect handled by Parse, day-of-month and
+// day-of-week are combined with AND rather than OR, matching systemd's own
+// semantics. Years is nil when the expression carries no year constraint
+// (it then matches every year in [calYearMin, calYearMax]); otherwise it
+// holds the set of matching years.
+type CalendarSchedule struct {
+	Second, Minute, Hour, Dom, Month, Weekday uint64
+	Years                                     map[int]bool
+	Location                                  *time.Location
+}
+
+// calYearMin and calYearMax bound the years a CalendarSchedule will search;
+// an expression that can't be satisfied inside this range is unsatisfiable.
+const (
+	calYearMin = 1970
+	calYearMax = 2199
+)
+
+// ParseCalendar parses a systemd.time "OnCalendar" expression into a
+// Schedule. The accepted grammar is:
+//
+//	[weekday-spec] year-month-day [hour:minute[:second]]
+//
+// where weekday-spec, year, month, day, hour, minute and second each accept
+// "*", a single value, a range ("a..b"), a step ("a/n" or "*/n") or a
+// comma-separated list of any of those. The time-of-day defaults to
+// "00:00:00" when omitted, and the shortcuts "minutely", "hourly", "daily",
+// "weekly", "monthly", "yearly" and "annually" are accepted in place of the
+// full grammar.
+func ParseCalendar(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty OnCalendar spec")
+	}
+
+	if sched, ok, err := calendarDescriptor(spec); ok || err != nil {
+		return sched, err
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 3 {
+		return nil, fmt.Errorf("invalid OnCalendar spec: %q", spec)
+	}
+
+	idx := 0
+	weekdayField := "*"
+	if looksLikeWeekday(fields[idx]) {
+		weekdayField = fields[idx]
+		idx++
+	}
+	if idx >= len(fields) {
+		return nil, fmt.Errorf("missing date spec in %q", spec)
+	}
+	dateField := fields[idx]
+	idx++
+	timeField := "00:00:00"
+	if idx < len(fields) {
+		timeField = fields[idx]
+		idx++
+	}
+	if idx != len(fields) {
+		return nil, fmt.Errorf("unexpected trailing fields in %q", spec)
+	}
+
+	weekday, err := parseField(normalizeRange(weekdayField), dow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse weekday: %s", err)
+	}
+
+	dateParts := strings.Split(dateField, "-")
+	if len(dateParts) != 3 {
+		return nil, fmt.Errorf("expected year-month-day date spec, found %q", dateField)
+	}
+	years, err := parseYearField(dateParts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse year: %s", err)
+	}
+	month, err := parseField(normalizeRange(dateParts[1]), months)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse month: %s", err)
+	}
+	dayOfMonth, err := parseField(normalizeRange(dateParts[2]), dom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse day-of-month: %s", err)
+	}
+
+	timeParts := strings.Split(timeField, ":")
+	if len(timeParts) < 2 || len(timeParts) > 3 {
+		return nil, fmt.Errorf("expected hour:minute[:second] time spec, found %q", timeField)
+	}
+	hour, err := parseField(normalizeRange(timeParts[0]), hours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hour: %s", err)
+	}
+	minute, err := parseField(normalizeRange(timeParts[1]), minutes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse minute: %s", err)
+	}
+	second := uint64(1) // bit 0: second 0
+	if len(timeParts) == 3 {
+		second, err = parseField(normalizeRange(timeParts[2]), seconds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse second: %s", err)
+		}
+	}
+
+	return &CalendarSchedule{
+		Second:   second,
+		Minute:   minute,
+		Hour:     hour,
+		Dom:      dayOfMonth,
+		Month:    month,
+		Weekday:  weekday,
+		Years:    years,
+		Location: time.Local,
+	}, nil
+}
+
+// calendarDescriptor expands the systemd shortcut names into their
+// equivalent full expression. The bool return is false when spec isn't one
+// of the recognized shortcuts.
+func calendarDescriptor(spec string) (Schedule, bool, error) {
+	var expanded string
+	switch spec {
+	case "minutely":
+		expanded = "*-*-* *:*:00"
+	case "hourly":
+		expanded = "*-*-* *:00:00"
+	case "daily", "midnight":
+		expanded = "*-*-* 00:00:00"
+	case "weekly":
+		expanded = "Mon *-*-* 00:00:00"
+	case "monthly":
+		expanded = "*-*-01 00:00:00"
+	case "yearly", "annually":
+		expanded = "*-01-01 00:00:00"
+	default:
+		return nil, false, nil
+	}
+	sched, err := ParseCalendar(expanded)
+	return sched, true, err
+}
+
+// looksLikeWeekday reports whether field names a weekday (possibly a list,
+// range or comma-separated set of them), as opposed to being the date spec.
+func looksLikeWeekday(field string) bool {
+	for _, part := range strings.Split(normalizeRange(field), ",") {
+		name := strings.Split(part, "-")[0]
+		if _, ok := dow.names[strings.ToLower(name)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRange rewrites systemd's ".." range separator to the "-" already
+// understood by getRange.
+func normalizeRange(field string) string {
+	return strings.ReplaceAll(field, "..", "-")
+}
+
+// parseYearField parses the year component of a date spec into the set of
+// matching years, or nil if the field is "*" (every year).
+func parseYearField(field string) (map[int]bool, error) {
+	field = normalizeRange(field)
+	if field == "*" {
+		return nil, nil
+	}
+
+	years := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangeAndStep := strings.Split(part, "/")
+		lowAndHigh := strings.Split(rangeAndStep[0], "-")
+
+		var start, end int
+		var err error
+		if lowAndHigh[0] == "*" {
+			start, end = calYearMin, calYearMax
+		} else {
+			start, err = strconv.Atoi(lowAndHigh[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid year %q", lowAndHigh[0])
+			}
+			end = start
+			if len(lowAndHigh) == 2 {
+				end, err = strconv.Atoi(lowAndHigh[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid year %q", lowAndHigh[1])
+				}
+			}
+		}
+
+		step := 1
+		if len(rangeAndStep) == 2 {
+			step, err = strconv.Atoi(rangeAndStep[1])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid year step in %q", part)
+			}
+			if lowAndHigh[0] == "*" || len(lowAndHigh) == 1 {
+				end = calYearMax
+			}
+		}
+
+		if start < calYearMin || end > calYearMax || start > end {
+			return nil, fmt.Errorf("year out of range (%d..%d) in %q", calYearMin, calYearMax, part)
+		}
+		for y := start; y <= end; y += step {
+			years[y] = true
+		}
+	}
+	return years, nil
+}
+
+// yearMatches reports whether year satisfies s's Years constraint.
+func (s *CalendarSchedule) yearMatches(year int) bool {
+	return s.Years == nil || s.Years[year]
+}
+
+// calDayMatches reports whether t's day-of-month and weekday both satisfy
+// s, the systemd AND semantics (contrast with cron's dayMatches OR rule).
+func calDayMatches(s *CalendarSchedule, t time.Time) bool {
+	return 1<<uint(t.Day())&s.Dom > 0 && 1<<uint(t.Weekday())&s.Weekday > 0
+}
+
+// Next returns the next time this schedule is activated, greater than the
+// given time. If no such time exists within [calYearMin, calYearMax], it
+// returns the zero time.
+func (s *CalendarSchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.Location
+	if loc == time.Local {
+		loc = origLocation
+	}
+	t = t.In(loc)
+	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	added := false
+
+WRAP:
+	if t.Year() > calYearMax {
+		return time.Time{}
+	}
+
+	for !s.yearMatches(t.Year()) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(1, 0, 0)
+		if t.Year() > calYearMax {
+			return time.Time{}
+		}
+	}
+
+	for 1<<uint(t.Month())&s.Month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !calDayMatches(s, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(1 * time.Hour)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(1 * time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(1 * time.Second)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLocation)
+}
+
+// Prev returns the last time this schedule was activated, strictly less
+// than the given time. If no such time exists within [calYearMin,
+// calYearMax], it returns the zero time.
+func (s *CalendarSchedule) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.Location
+	if loc == time.Local {
+		loc = origLocation
+	}
+	t = t.In(loc)
+	t = t.Add(-time.Duration(t.Nanosecond()) * time.Nanosecond)
+	t = t.Add(-1 * time.Second)
+
+	added := false
+
+WRAP:
+	if t.Year() < calYearMin {
+		return time.Time{}
+	}
+
+	for !s.yearMatches(t.Year()) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), 12, 31, 23, 59, 59, 0, loc)
+		}
+		t = t.AddDate(-1, 0, 0)
+		if t.Year() < calYearMin {
+			return time.Time{}
+		}
+	}
+
+	monthChanged := false
+	for 1<<uint(t.Month())&s.Month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 23, 59, 59, 0, loc)
+		} else {
+			t = time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), 0, loc)
+		}
+		monthChanged = true
+		t = t.AddDate(0, -1, 0)
+		if t.Month() == time.December {
+			goto WRAP
+		}
+	}
+	if monthChanged {
+		t = endOfMonth(t, loc)
+	}
+
+	for !calDayMatches(s, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
+		}
+		t = t.AddDate(0, 0, -1)
+		if t.Day() == daysIn(t.Month(), t.Year()) {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		if !added {
+			added = true
+			t = endOfUnit(t, time.Hour)
+		}
+		t = t.Add(-1 * time.Hour)
+		if t.Hour() == 23 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		if !added {
+			added = true
+			t = endOfUnit(t, time.Minute)
+		}
+		t = t.Add(-1 * time.Minute)
+		if t.Minute() == 59 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		if !added {
+			added = true
+		}
+		t = t.Add(-1 * time.Second)
+		if t.Second() == 59 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLocation)
+}
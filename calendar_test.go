@@ -0,0 +1,140 @@
+package cron
+
+import (
+	"testing"
+)
+
+func TestParseCalendarErrors(t *testing.T) {
+	invalidSpecs := []string{
+		"",
+		"*-* 10:00", // date spec needs year-month-day
+		"Xyz *-*-* 10:00",
+		"*-*-* 25:00",
+		"*-*-* 10:99",
+	}
+	for _, spec := range invalidSpecs {
+		if _, err := ParseCalendar(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestCalendarNext(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Plain time-of-day, every day.
+		{"Mon Jul 9 02:00:00 2012", "*-*-* 02:30:00", "Mon Jul 9 02:30:00 2012"},
+		{"Mon Jul 9 02:30:00 2012", "*-*-* 02:30:00", "Tue Jul 10 02:30:00 2012"},
+
+		// Weekday range, no seconds given.
+		{"Mon Jul 9 09:00 2012", "Mon..Fri *-*-* 10:00", "Mon Jul 9 10:00 2012"},
+		{"Fri Jul 13 10:00 2012", "Mon..Fri *-*-* 10:00", "Mon Jul 16 10:00 2012"},
+
+		// Day-of-month step.
+		{"Mon Jul 9 00:00 2012", "*-*-01/2 00:00:00", "Wed Jul 11 00:00:00 2012"},
+
+		// Shortcuts.
+		{"Mon Jul 9 15:05 2012", "weekly", "Mon Jul 16 00:00:00 2012"},
+		{"Mon Jul 9 15:05 2012", "daily", "Tue Jul 10 00:00:00 2012"},
+		{"Mon Jul 9 15:05 2012", "monthly", "Wed Aug 1 00:00:00 2012"},
+		{"Mon Jul 9 15:05 2012", "yearly", "Tue Jan 1 00:00:00 2013"},
+
+		// Year constraint.
+		{"Mon Jul 9 00:00 2012", "2013-*-* 00:00:00", "Tue Jan 1 00:00:00 2013"},
+
+		// Unsatisfiable.
+		{"Mon Jul 9 00:00 2012", "*-02-30 00:00:00", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := ParseCalendar(c.spec)
+		if err != nil {
+			t.Errorf("%q: %v", c.spec, err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %q: (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestCalendarPrev(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"Mon Jul 9 02:30:00 2012", "*-*-* 02:30:00", "Sun Jul 8 02:30:00 2012"},
+		{"Mon Jul 16 10:00 2012", "Mon..Fri *-*-* 10:00", "Fri Jul 13 10:00:00 2012"},
+		{"Mon Jul 9 15:05 2012", "monthly", "Sun Jul 1 00:00:00 2012"},
+		{"Mon Jul 9 00:00 2012", "*-02-30 00:00:00", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := ParseCalendar(c.spec)
+		if err != nil {
+			t.Errorf("%q: %v", c.spec, err)
+			continue
+		}
+		actual := sched.Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %q: (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+// dstCase and runDSTProbe are shared between the cron spec tests and the
+// OnCalendar tests, since both Schedule implementations must wrap around
+// the Mar/Nov US DST transitions identically.
+type dstCase struct {
+	time, spec string
+	expected   string
+}
+
+func runDSTProbe(t *testing.T, parse func(string) (Schedule, error), cases []dstCase) {
+	t.Helper()
+	for _, c := range cases {
+		sched, err := parse(c.spec)
+		if err != nil {
+			t.Errorf("%q: %v", c.spec, err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %q: (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestCalendarNextDST(t *testing.T) {
+	runDSTProbe(t, ParseCalendar, []dstCase{
+		// Spring forward: 02:30 never occurs on Mar 11 2012 (clocks jump
+		// 01:59:59 EST straight to 03:00:00 EDT), so a spec pinned to that
+		// exact day has to wait a full year for the next Mar 11.
+		{"2012-03-11T00:00:00-0500", "*-03-11 02:30:00", "2013-03-11T02:30:00-0400"},
+		// Fall back: 2am EDT (-4) -> 1am EST (-5); the 1am hour repeats
+		// and 02:30 occurs once, in EST.
+		{"2012-11-04T00:00:00-0400", "*-11-04 02:30:00", "2012-11-04T02:30:00-0500"},
+	})
+}
+
+func TestCalendarPrevDST(t *testing.T) {
+	for _, c := range []dstCase{
+		{"2012-11-04T03:00:00-0500", "*-*-* 02:00:00", "2012-11-04T02:00:00-0500"},
+	} {
+		sched, err := ParseCalendar(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := sched.Prev(getTimeTZ(c.time))
+		expected := getTimeTZ(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %q: (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
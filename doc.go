@@ -0,0 +1,34 @@
+// Package cron implements a cron spec parser and a Schedule type usable to
+// calculate the next (or previous) activation time for a given cron spec.
+//
+// # Cron expression format
+//
+// A cron expression represents a set of times, using 6 space-separated
+// fields.
+//
+//	Field name   | Mandatory? | Allowed values  | Allowed special characters
+//	----------   | ---------- | --------------  | --------------------------
+//	Seconds      | Yes        | 0-59            | * / , -
+//	Minutes      | Yes        | 0-59            | * / , -
+//	Hours        | Yes        | 0-23            | * / , -
+//	Day of month | Yes        | 1-31            | * / , - ?
+//	Month        | Yes        | 1-12 or JAN-DEC | * / , -
+//	Day of week  | No         | 0-6 or SUN-SAT  | * / , - ?
+//
+// When the day-of-week field is omitted it defaults to "*", so a 5-field
+// expression is accepted as well as a 6-field one.
+//
+// # Predefined schedules
+//
+// Instead of the 5/6 field syntax, Parse also accepts one of the following
+// shortcuts:
+//
+//	Entry                  | Equivalent To
+//	-----                  | -------------
+//	@yearly (or @annually) | 0 0 0 1 1 *
+//	@monthly               | 0 0 0 1 * *
+//	@weekly                | 0 0 0 * * 0
+//	@daily (or @midnight)  | 0 0 0 * * *
+//	@hourly                | 0 0 * * * *
+//	@every <duration>      | fires every time.Duration, e.g. @every 1h30m
+package cron
@@ -0,0 +1,104 @@
+package cron
+
+import "time"
+
+// defaultFilterMaxIterations bounds how many consecutive rejections a
+// ScheduleFilter will walk through before giving up, preserving the
+// "unsatisfiable returns zero" contract even for a predicate that never
+// passes (e.g. a Holidays set covering every day of the year).
+const defaultFilterMaxIterations = 366 * 5
+
+// ScheduleFilter wraps a Schedule, skipping any candidate activation for
+// which Skip reports true and advancing (for Next) or retreating (for Prev)
+// to the next one that doesn't. It gives up and returns the zero time after
+// MaxIterations consecutive rejections; MaxIterations defaults to
+// defaultFilterMaxIterations when left at zero.
+type ScheduleFilter struct {
+	Schedule      Schedule
+	Skip          func(time.Time) bool
+	MaxIterations int
+}
+
+// WithBusinessDays wraps s so that Next and Prev only return activations
+// for which isBusinessDay reports true. Combine with Weekdays and Holidays
+// to exclude, e.g., weekends and a holiday calendar.
+func WithBusinessDays(s Schedule, isBusinessDay func(time.Time) bool) Schedule {
+	return WithSkip(s, func(t time.Time) bool { return !isBusinessDay(t) })
+}
+
+// WithSkip wraps s so that Next and Prev advance past any activation for
+// which skip reports true.
+func WithSkip(s Schedule, skip func(time.Time) bool) Schedule {
+	return &ScheduleFilter{Schedule: s, Skip: skip, MaxIterations: defaultFilterMaxIterations}
+}
+
+// Next returns the next activation of the wrapped Schedule for which Skip
+// reports false, or the zero time if none is found within MaxIterations
+// attempts.
+func (f *ScheduleFilter) Next(t time.Time) time.Time {
+	for i := 0; i < f.maxIterations(); i++ {
+		t = f.Schedule.Next(t)
+		if t.IsZero() || !f.Skip(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the last activation of the wrapped Schedule for which Skip
+// reports false, or the zero time if none is found within MaxIterations
+// attempts.
+func (f *ScheduleFilter) Prev(t time.Time) time.Time {
+	for i := 0; i < f.maxIterations(); i++ {
+		t = f.Schedule.Prev(t)
+		if t.IsZero() || !f.Skip(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (f *ScheduleFilter) maxIterations() int {
+	if f.MaxIterations > 0 {
+		return f.MaxIterations
+	}
+	return defaultFilterMaxIterations
+}
+
+// Weekdays returns a predicate reporting whether t falls Monday through
+// Friday. It's meant to be passed (optionally combined with Holidays) as
+// the isBusinessDay argument to WithBusinessDays.
+func Weekdays() func(time.Time) bool {
+	return func(t time.Time) bool {
+		switch t.Weekday() {
+		case time.Saturday, time.Sunday:
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+// Holidays returns a predicate reporting whether t falls on one of the
+// dates in set (set's values must be true; false entries are ignored).
+// Dates are compared at day granularity after normalizing both set and t
+// into loc, so callers in different zones still agree on which calendar
+// day a holiday falls on.
+func Holidays(set map[time.Time]bool, loc *time.Location) func(time.Time) bool {
+	days := make(map[time.Time]bool, len(set))
+	for d, ok := range set {
+		if ok {
+			days[startOfDay(d, loc)] = true
+		}
+	}
+	return func(t time.Time) bool {
+		return days[startOfDay(t, loc)]
+	}
+}
+
+// startOfDay returns the midnight instant, in loc, of the calendar day t
+// falls on when viewed in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
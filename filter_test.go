@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithBusinessDays(t *testing.T) {
+	sched, err := Parse("0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holidays := Holidays(map[time.Time]bool{
+		// Independence Day 2012 (a Wednesday).
+		time.Date(2012, time.July, 4, 0, 0, 0, 0, loc): true,
+	}, loc)
+	isBusinessDay := func(tm time.Time) bool {
+		return Weekdays()(tm) && !holidays(tm)
+	}
+
+	filtered := WithBusinessDays(sched, isBusinessDay)
+
+	runs := []struct {
+		time     string
+		expected string
+	}{
+		// Friday 9am rolls to Monday, skipping the weekend.
+		{"Fri Jul 6 09:00 2012", "Mon Jul 9 09:00 2012"},
+		// Tuesday the 3rd rolls to Thursday the 5th, skipping the holiday.
+		{"Tue Jul 3 09:00 2012", "Thu Jul 5 09:00 2012"},
+	}
+	for _, r := range runs {
+		actual := filtered.Next(getTime(r.time))
+		expected := getTime(r.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", r.time, expected, actual)
+		}
+	}
+}
+
+func TestWithSkipUnsatisfiable(t *testing.T) {
+	sched, err := Parse("0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered := &ScheduleFilter{
+		Schedule:      sched,
+		Skip:          func(time.Time) bool { return true },
+		MaxIterations: 10,
+	}
+
+	if actual := filtered.Next(getTime("Mon Jul 9 00:00 2012")); !actual.IsZero() {
+		t.Errorf("expected zero time, got %v", actual)
+	}
+	if actual := filtered.Prev(getTime("Mon Jul 9 00:00 2012")); !actual.IsZero() {
+		t.Errorf("expected zero time, got %v", actual)
+	}
+}
+
+func TestWithSkipPrev(t *testing.T) {
+	sched, err := Parse("0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := WithBusinessDays(sched, Weekdays())
+
+	actual := filtered.Prev(getTime("Mon Jul 9 09:00 2012"))
+	expected := getTime("Fri Jul 6 09:00 2012")
+	if !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+}
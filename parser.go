@@ -0,0 +1,250 @@
+package cron
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes a job's duty cycle: the set of instants a spec matches.
+type Schedule interface {
+	// Next returns the first activation time later than the given time.
+	// It returns the zero time if no such time exists.
+	Next(time.Time) time.Time
+
+	// Prev returns the last activation time before the given time.
+	// It returns the zero time if no such time exists.
+	Prev(time.Time) time.Time
+}
+
+// bounds provides a range of acceptable values (plus a map of name to value,
+// used for the month and day-of-week fields).
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	seconds = bounds{0, 59, nil}
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	dom     = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dow = bounds{0, 6, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// starBit is set on a field's bitmask when the field was specified as "*"
+// (or "?"), so that the day-of-month/day-of-week "OR" interaction described
+// at dayMatches can tell a wildcard apart from an explicit value.
+const starBit = 1 << 63
+
+// Parse parses a cron spec string, returning a Schedule that activates on
+// every instant described by the string. It accepts either the 6-field
+// "seconds minutes hours dom month dow" syntax, the 5-field syntax with
+// day-of-week omitted (it defaults to "*"), or one of the "@every"/predefined shortcuts
+// described in the package docs.
+func Parse(spec string) (Schedule, error) {
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("empty spec string")
+	}
+	if spec[0] == '@' {
+		return parseDescriptor(spec)
+	}
+
+	fields := strings.Fields(spec)
+
+	switch len(fields) {
+	case 5:
+		// Day-of-week defaults to "*" when omitted.
+		fields = append(fields, "*")
+	case 6:
+		// ok
+	default:
+		return nil, fmt.Errorf("expected 5 or 6 fields, found %d: %q", len(fields), spec)
+	}
+
+	second, err := parseField(fields[0], seconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seconds: %s", err)
+	}
+	minute, err := parseField(fields[1], minutes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse minutes: %s", err)
+	}
+	hour, err := parseField(fields[2], hours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hours: %s", err)
+	}
+	dayOfMonth, err := parseField(fields[3], dom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse day-of-month: %s", err)
+	}
+	month, err := parseField(fields[4], months)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse month: %s", err)
+	}
+	dayOfWeek, err := parseField(fields[5], dow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse day-of-week: %s", err)
+	}
+
+	return &SpecSchedule{
+		Second:   second,
+		Minute:   minute,
+		Hour:     hour,
+		Dom:      dayOfMonth,
+		Month:    month,
+		Dow:      dayOfWeek,
+		Location: time.Local,
+	}, nil
+}
+
+// parseDescriptor handles "@" shortcuts: @yearly, @annually, @monthly,
+// @weekly, @daily, @midnight, @hourly, and @every <duration>.
+func parseDescriptor(descriptor string) (Schedule, error) {
+	switch descriptor {
+	case "@yearly", "@annually":
+		return Parse("0 0 0 1 1 *")
+	case "@monthly":
+		return Parse("0 0 0 1 * *")
+	case "@weekly":
+		return Parse("0 0 0 * * 0")
+	case "@daily", "@midnight":
+		return Parse("0 0 0 * * *")
+	case "@hourly":
+		return Parse("0 0 * * * *")
+	}
+
+	const every = "@every "
+	if strings.HasPrefix(descriptor, every) {
+		duration, err := time.ParseDuration(descriptor[len(every):])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse duration %q: %s", descriptor, err)
+		}
+		return ConstantDelaySchedule{Delay: duration}, nil
+	}
+
+	return nil, fmt.Errorf("unrecognized descriptor: %q", descriptor)
+}
+
+// parseField parses a single cron field (a comma-separated list of ranges)
+// into a bitmask.
+func parseField(field string, r bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		bit, err := getRange(expr, r)
+		if err != nil {
+			return 0, err
+		}
+		bits |= bit
+	}
+	return bits, nil
+}
+
+// getRange parses a single range expression, e.g. "5-10/2" or "*" or "Mon".
+func getRange(expr string, r bounds) (uint64, error) {
+	var (
+		start, end, step uint
+		rangeAndStep     = strings.Split(expr, "/")
+		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
+		singleDigit      = len(lowAndHigh) == 1
+		extra            uint64
+		err              error
+	)
+
+	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
+		start = r.min
+		end = r.max
+		extra = starBit
+	} else {
+		start, err = parseIntOrName(lowAndHigh[0], r.names)
+		if err != nil {
+			return 0, err
+		}
+		switch len(lowAndHigh) {
+		case 1:
+			end = start
+		case 2:
+			end, err = parseIntOrName(lowAndHigh[1], r.names)
+			if err != nil {
+				return 0, err
+			}
+		default:
+			return 0, fmt.Errorf("too many hyphens: %q", expr)
+		}
+	}
+
+	switch len(rangeAndStep) {
+	case 1:
+		step = 1
+	case 2:
+		step, err = mustParseInt(rangeAndStep[1])
+		if err != nil {
+			return 0, err
+		}
+		// e.g. "5/15" means "5-max/15".
+		if singleDigit {
+			end = r.max
+		}
+	default:
+		return 0, fmt.Errorf("too many slashes: %q", expr)
+	}
+
+	if start < r.min {
+		return 0, fmt.Errorf("beginning of range (%d) below minimum (%d): %q", start, r.min, expr)
+	}
+	if end > r.max {
+		return 0, fmt.Errorf("end of range (%d) above maximum (%d): %q", end, r.max, expr)
+	}
+	if start > end {
+		return 0, fmt.Errorf("beginning of range (%d) beyond end of range (%d): %q", start, end, expr)
+	}
+	if step == 0 {
+		return 0, fmt.Errorf("step of range should be a positive number: %q", expr)
+	}
+
+	return getBits(start, end, step) | extra, nil
+}
+
+// parseIntOrName parses a number, or (if present) a name from the names map.
+func parseIntOrName(expr string, names map[string]uint) (uint, error) {
+	if names != nil {
+		if namedInt, ok := names[strings.ToLower(expr)]; ok {
+			return namedInt, nil
+		}
+	}
+	return mustParseInt(expr)
+}
+
+// mustParseInt parses the given expression as a non-negative integer.
+func mustParseInt(expr string) (uint, error) {
+	num, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse int from %q: %s", expr, err)
+	}
+	if num < 0 {
+		return 0, fmt.Errorf("negative number (%d) not allowed: %q", num, expr)
+	}
+	return uint(num), nil
+}
+
+// getBits sets all bits in [min, max], modulo the given step size.
+func getBits(min, max, step uint) uint64 {
+	var bits uint64
+
+	if step == 1 {
+		return ^(math.MaxUint64 << (max + 1)) & (math.MaxUint64 << min)
+	}
+
+	for i := min; i <= max; i += step {
+		bits |= 1 << i
+	}
+	return bits
+}
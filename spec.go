@@ -0,0 +1,247 @@
+package cron
+
+import "time"
+
+// SpecSchedule is a Schedule built from a parsed cron spec. Second, Minute,
+// Hour, Dom (day of month) and Month are represented as a bitmask of valid
+// values, with the lowest order bit representing the lowest value. Dom and
+// Dow (day of week) additionally carry the starBit when given as "*" or "?",
+// so that Next/Prev can tell a wildcard apart from an explicit enumeration
+// when deciding whether day-of-month and day-of-week combine with AND or OR
+// semantics.
+type SpecSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+	Location                              *time.Location
+}
+
+// yearLimit bounds how many years Next/Prev will search before giving up and
+// reporting the spec as unsatisfiable.
+const yearLimit = 5
+
+// Next returns the next time this schedule is activated, greater than the
+// given time. If no such time exists, it returns the zero time.
+func (s *SpecSchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.Location
+	if loc == time.Local {
+		loc = origLocation
+	}
+	t = t.In(loc)
+
+	// Start at the earliest possible time (the upcoming second).
+	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	added := false
+	limit := t.Year() + yearLimit
+
+WRAP:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&s.Month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !dayMatches(s, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(1 * time.Hour)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(1 * time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(1 * time.Second)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLocation)
+}
+
+// Prev returns the last time this schedule was activated, strictly less
+// than the given time. If no such time exists, it returns the zero time.
+func (s *SpecSchedule) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.Location
+	if loc == time.Local {
+		loc = origLocation
+	}
+	t = t.In(loc)
+
+	// Start at the latest possible time (the preceding second).
+	t = t.Add(-time.Duration(t.Nanosecond()) * time.Nanosecond)
+	t = t.Add(-1 * time.Second)
+
+	added := false
+	limit := t.Year() - yearLimit
+
+WRAP:
+	if t.Year() < limit {
+		return time.Time{}
+	}
+
+	monthChanged := false
+	for 1<<uint(t.Month())&s.Month == 0 {
+		// Pin the day to 1 before subtracting a month: AddDate on a
+		// day near the end of the month can otherwise overflow into
+		// the following month (e.g. Mar 31 - 1 month = Mar 3, not Feb).
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 23, 59, 59, 0, loc)
+		} else {
+			t = time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), 0, loc)
+		}
+		monthChanged = true
+		t = t.AddDate(0, -1, 0)
+		if t.Month() == time.December {
+			goto WRAP
+		}
+	}
+	if monthChanged {
+		t = endOfMonth(t, loc)
+	}
+
+	for !dayMatches(s, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
+		}
+		t = t.AddDate(0, 0, -1)
+		if t.Day() == daysIn(t.Month(), t.Year()) {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		if !added {
+			added = true
+			// Reconstructing this via time.Date would pick an
+			// arbitrary side of a fall-back DST transition; walking
+			// up from the absolute instant instead keeps whichever
+			// occurrence of the hour t actually started in.
+			t = endOfUnit(t, time.Hour)
+		}
+		t = t.Add(-1 * time.Hour)
+		if t.Hour() == 23 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		if !added {
+			added = true
+			t = endOfUnit(t, time.Minute)
+		}
+		t = t.Add(-1 * time.Minute)
+		if t.Minute() == 59 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		if !added {
+			added = true
+		}
+		t = t.Add(-1 * time.Second)
+		if t.Second() == 59 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLocation)
+}
+
+// endOfMonth rounds t forward to the last representable instant of its
+// month, used by Prev when a month is rejected and the search needs to
+// resume from the end of the previous one.
+func endOfMonth(t time.Time, loc *time.Location) time.Time {
+	last := daysIn(t.Month(), t.Year())
+	return time.Date(t.Year(), t.Month(), last, 23, 59, 59, 0, loc)
+}
+
+// endOfUnit returns the last second within the d-aligned unit containing t
+// (e.g. d=time.Hour returns the :59:59 that closes t's hour). Unlike
+// reconstructing the value with time.Date, this works entirely in absolute
+// time, so it can't jump to the wrong side of a fall-back DST transition.
+func endOfUnit(t time.Time, d time.Duration) time.Time {
+	return t.Truncate(d).Add(d - time.Second)
+}
+
+// daysIn reports the number of days in the given month/year.
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// dayMatches reports whether the day-of-month and day-of-week fields of the
+// given spec agree that t is a valid day. If either field is a wildcard,
+// both must match (AND), which in effect means only the explicit field is
+// checked; if both are explicit, either one matching is enough (OR).
+func dayMatches(s *SpecSchedule, t time.Time) bool {
+	var (
+		domMatch = 1<<uint(t.Day())&s.Dom > 0
+		dowMatch = 1<<uint(t.Weekday())&s.Dow > 0
+	)
+
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// ConstantDelaySchedule represents a simple recurring duty cycle, such as
+// "@every 5m", from which the next and previous activation times can be
+// calculated with simple arithmetic.
+type ConstantDelaySchedule struct {
+	Delay time.Duration
+}
+
+// Next returns the next time this schedule is activated, greater than the
+// given time.
+func (s ConstantDelaySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
+
+// Prev returns the last time this schedule was activated, strictly less
+// than the given time.
+func (s ConstantDelaySchedule) Prev(t time.Time) time.Time {
+	return t.Add(-s.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
@@ -0,0 +1,298 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeWindow restricts activations to a recurring time-of-day range on a
+// set of weekdays, e.g. the proxmox-style "Mon..Fri 08:00-18:00". Start and
+// End are offsets from local midnight; End <= Start represents a window
+// that wraps past midnight (e.g. 22:00-02:00). Weekday is a bitmask using
+// the same 0=Sunday..6=Saturday convention as SpecSchedule.Dow, and refers
+// to the day the window *opens* on.
+type TimeWindow struct {
+	Start, End time.Duration
+	Weekday    uint64
+	Location   *time.Location
+}
+
+// defaultWindowMaxJumps bounds how many times WithWindow will fast-forward
+// to the next window opening before giving up, preserving the
+// "unsatisfiable returns zero" contract.
+const defaultWindowMaxJumps = 366 * 5
+
+// ParseWindow parses a proxmox-time-style window spec, "[weekday-spec]
+// HH:MM[:SS]-HH:MM[:SS]", into a TimeWindow. weekday-spec follows the same
+// grammar as a cron day-of-week field ("*", "Mon", "Mon..Fri", "Mon,Wed")
+// and defaults to every day when omitted. The returned TimeWindow's
+// Location is time.Local; set it explicitly for any other zone.
+func ParseWindow(spec string) (TimeWindow, error) {
+	fields := strings.Fields(strings.TrimSpace(spec))
+
+	var weekdayField, rangeField string
+	switch len(fields) {
+	case 1:
+		weekdayField, rangeField = "*", fields[0]
+	case 2:
+		weekdayField, rangeField = fields[0], fields[1]
+	default:
+		return TimeWindow{}, fmt.Errorf("invalid window spec: %q", spec)
+	}
+
+	weekday, err := parseField(normalizeRange(weekdayField), dow)
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("failed to parse weekday: %s", err)
+	}
+
+	bounds := strings.SplitN(rangeField, "-", 2)
+	if len(bounds) != 2 {
+		return TimeWindow{}, fmt.Errorf("expected HH:MM-HH:MM window, found %q", rangeField)
+	}
+	start, err := parseClock(bounds[0])
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("failed to parse window start: %s", err)
+	}
+	end, err := parseClock(bounds[1])
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("failed to parse window end: %s", err)
+	}
+
+	return TimeWindow{Start: start, End: end, Weekday: weekday, Location: time.Local}, nil
+}
+
+// parseClock parses "HH:MM" or "HH:MM:SS" into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("expected HH:MM[:SS], found %q", s)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	second := 0
+	if len(parts) == 3 {
+		second, err = strconv.Atoi(parts[2])
+		if err != nil || second < 0 || second > 59 {
+			return 0, fmt.Errorf("invalid second in %q", s)
+		}
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second, nil
+}
+
+func (w TimeWindow) location() *time.Location {
+	if w.Location != nil {
+		return w.Location
+	}
+	return time.Local
+}
+
+func (w TimeWindow) dayAllowed(wd time.Weekday) bool {
+	return w.Weekday&(1<<uint(wd)) != 0
+}
+
+// contains reports whether t, interpreted in w's Location, falls inside w.
+func (w TimeWindow) contains(t time.Time) bool {
+	loc := w.location()
+	lt := t.In(loc)
+	offset := clockOffset(lt)
+
+	if w.End > w.Start {
+		return offset >= w.Start && offset < w.End && w.dayAllowed(lt.Weekday())
+	}
+
+	// Wrapping window: e.g. 22:00-02:00 covers [Start,24:00) on its opening
+	// day and [00:00,End) on the following one.
+	if offset >= w.Start {
+		return w.dayAllowed(lt.Weekday())
+	}
+	if offset < w.End {
+		yesterday := lt.AddDate(0, 0, -1)
+		return w.dayAllowed(yesterday.Weekday())
+	}
+	return false
+}
+
+// nextOpen returns the earliest opening (Start) of w at or after from, or
+// the zero time if w's Weekday mask matches no day at all. If from is
+// already inside w, the result is the following opening, not from itself.
+func (w TimeWindow) nextOpen(from time.Time) time.Time {
+	loc := w.location()
+	from = from.In(loc)
+	for i := 0; i < 8; i++ {
+		day := from.AddDate(0, 0, i)
+		if !w.dayAllowed(day.Weekday()) {
+			continue
+		}
+		open := dateAtOffset(day, w.Start, loc)
+		if !open.Before(from) {
+			return open
+		}
+	}
+	return time.Time{}
+}
+
+// prevClose returns the closing instant, <= before, of the most recent
+// window at or before before, or the zero time if w's Weekday mask matches
+// no day at all. The instant itself is never inside w (End is exclusive),
+// so callers can pass it straight to a Schedule's strict less-than Prev.
+func (w TimeWindow) prevClose(before time.Time) time.Time {
+	loc := w.location()
+	before = before.In(loc)
+	for i := 0; i < 8; i++ {
+		day := before.AddDate(0, 0, -i)
+		if !w.dayAllowed(day.Weekday()) {
+			continue
+		}
+		closeDay := day
+		if w.End <= w.Start {
+			closeDay = day.AddDate(0, 0, 1)
+		}
+		close := dateAtOffset(closeDay, w.End, loc)
+		if !close.After(before) {
+			return close
+		}
+	}
+	return time.Time{}
+}
+
+// clockOffset returns t's time-of-day as an offset from midnight, reading
+// t's own wall-clock fields rather than subtracting instants, so it can't
+// drift across a DST transition.
+func clockOffset(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// dateAtOffset builds the wall-clock instant offset past midnight on day's
+// date, in loc. Like clockOffset, it goes through time.Date rather than
+// adding offset to a midnight instant, so a DST transition between
+// midnight and offset can't shift the result.
+//
+// If offset names a wall-clock time that doesn't exist on day (it falls
+// inside a spring-forward gap), time.Date silently normalizes it to an
+// earlier, pre-transition instant rather than erroring, which would
+// otherwise make callers recompute the same non-advancing instant forever.
+// dateAtOffset detects that case by checking the result round-trips back
+// to the requested h:m:s, and if it doesn't, clamps forward to the first
+// instant that exists on day.
+func dateAtOffset(day time.Time, offset time.Duration, loc *time.Location) time.Time {
+	secs := int64(offset / time.Second)
+	h, m, s := int(secs/3600), int((secs%3600)/60), int(secs%60)
+	t := time.Date(day.Year(), day.Month(), day.Day(), h, m, s, 0, loc)
+	if lt := t.In(loc); lt.Hour() == h && lt.Minute() == m && lt.Second() == s {
+		return t
+	}
+	return dstGapEnd(day, loc)
+}
+
+// dstGapEnd returns the first instant on or after local midnight on day, in
+// loc, whose UTC offset differs from midnight's — the end of a
+// spring-forward gap, assuming day has one (every real-world zone changes
+// offset at most once per day).
+func dstGapEnd(day time.Time, loc *time.Location) time.Time {
+	lo := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	hi := lo.AddDate(0, 0, 1)
+	_, midnightOffset := lo.Zone()
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, off := mid.Zone(); off == midnightOffset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// windowSchedule wraps a Schedule so Next/Prev only return activations
+// falling inside one of its windows.
+type windowSchedule struct {
+	schedule Schedule
+	windows  []TimeWindow
+}
+
+// WithWindow wraps s so that Next and Prev only return activations that
+// fall inside one of windows. When a candidate misses every window, the
+// search jumps directly to that window's next opening (rather than
+// re-probing s one second at a time) before asking s for another
+// candidate, up to a bounded number of jumps.
+func WithWindow(s Schedule, windows ...TimeWindow) Schedule {
+	return &windowSchedule{schedule: s, windows: windows}
+}
+
+func (ws *windowSchedule) contains(t time.Time) bool {
+	if len(ws.windows) == 0 {
+		return true
+	}
+	for _, w := range ws.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ws *windowSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < defaultWindowMaxJumps; i++ {
+		next := ws.schedule.Next(t)
+		if next.IsZero() {
+			return next
+		}
+		if ws.contains(next) {
+			return next
+		}
+
+		var open time.Time
+		for _, w := range ws.windows {
+			o := w.nextOpen(next)
+			if o.IsZero() {
+				continue
+			}
+			if open.IsZero() || o.Before(open) {
+				open = o
+			}
+		}
+		if open.IsZero() {
+			return time.Time{}
+		}
+		t = open.Add(-time.Second)
+	}
+	return time.Time{}
+}
+
+func (ws *windowSchedule) Prev(t time.Time) time.Time {
+	for i := 0; i < defaultWindowMaxJumps; i++ {
+		prev := ws.schedule.Prev(t)
+		if prev.IsZero() {
+			return prev
+		}
+		if ws.contains(prev) {
+			return prev
+		}
+
+		var close time.Time
+		for _, w := range ws.windows {
+			c := w.prevClose(prev)
+			if c.IsZero() {
+				continue
+			}
+			if close.IsZero() || c.After(close) {
+				close = c
+			}
+		}
+		if close.IsZero() {
+			return time.Time{}
+		}
+		// close is End-exclusive, so the inner Schedule's strict
+		// less-than search can land exactly on it.
+		t = close
+	}
+	return time.Time{}
+}
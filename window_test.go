@@ -0,0 +1,166 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow("Mon..Fri 08:00-18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Start != 8*time.Hour || w.End != 18*time.Hour {
+		t.Errorf("got Start=%v End=%v", w.Start, w.End)
+	}
+	want := uint64(1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday)
+	if w.Weekday != want {
+		t.Errorf("got Weekday=%b, want %b", w.Weekday, want)
+	}
+
+	if w, err := ParseWindow("22:00-02:00"); err != nil || w.Start != 22*time.Hour || w.End != 2*time.Hour {
+		t.Errorf("got %+v, %v", w, err)
+	}
+
+	invalidSpecs := []string{
+		"",
+		"Mon..Fri 08:00",
+		"Mon..Fri 08:00-18:00 extra",
+		"Xyz 08:00-18:00",
+		"25:00-18:00",
+	}
+	for _, spec := range invalidSpecs {
+		if _, err := ParseWindow(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestWithWindow(t *testing.T) {
+	sched, err := Parse("0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := ParseWindow("Mon..Fri 08:00-18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := WithWindow(sched, window)
+
+	runs := []struct {
+		time     string
+		expected string
+	}{
+		// Inside the window already: next hourly tick still qualifies.
+		{"Mon Jul 9 09:00 2012", "Mon Jul 9 10:00 2012"},
+		// Past closing: jumps straight to the next day's opening.
+		{"Mon Jul 9 18:00 2012", "Tue Jul 10 08:00 2012"},
+		// Friday evening rolls over the weekend to Monday morning.
+		{"Fri Jul 13 19:00 2012", "Mon Jul 16 08:00 2012"},
+	}
+	for _, r := range runs {
+		actual := filtered.Next(getTime(r.time))
+		expected := getTime(r.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", r.time, expected, actual)
+		}
+	}
+}
+
+func TestWithWindowPrev(t *testing.T) {
+	sched, err := Parse("0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := ParseWindow("Mon..Fri 08:00-18:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := WithWindow(sched, window)
+
+	actual := filtered.Prev(getTime("Mon Jul 9 07:00 2012"))
+	expected := getTime("Fri Jul 6 17:00 2012")
+	if !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+}
+
+func TestWithWindowWraps(t *testing.T) {
+	sched, err := Parse("0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := ParseWindow("22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := WithWindow(sched, window)
+
+	if actual := filtered.Next(getTime("Mon Jul 9 23:00 2012")); !actual.Equal(getTime("Tue Jul 10 00:00 2012")) {
+		t.Errorf("got %v", actual)
+	}
+	if actual := filtered.Next(getTime("Mon Jul 9 03:00 2012")); !actual.Equal(getTime("Mon Jul 9 22:00 2012")) {
+		t.Errorf("got %v", actual)
+	}
+}
+
+func TestWithWindowUnsatisfiable(t *testing.T) {
+	sched, err := Parse("0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := TimeWindow{Start: 8 * time.Hour, End: 9 * time.Hour, Weekday: 0}
+	filtered := WithWindow(sched, window)
+
+	if actual := filtered.Next(getTime("Mon Jul 9 00:00 2012")); !actual.IsZero() {
+		t.Errorf("expected zero time, got %v", actual)
+	}
+	if actual := filtered.Prev(getTime("Mon Jul 9 00:00 2012")); !actual.IsZero() {
+		t.Errorf("expected zero time, got %v", actual)
+	}
+}
+
+func TestWithWindowDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched, err := Parse("0 0,30 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A window closing at 3am excludes the exact instant US clocks land on
+	// after the Mar 11 2012 spring-forward (1:59:59 EST -> 3:00:00 EDT), so
+	// the next opening has to be computed in wall time the following day.
+	window := TimeWindow{Start: 1 * time.Hour, End: 3 * time.Hour, Weekday: ^uint64(0), Location: loc}
+	filtered := WithWindow(sched, window)
+
+	actual := filtered.Next(getTimeTZ("2012-03-11T01:30:00-0500"))
+	expected := getTimeTZ("2012-03-12T01:00:00-0400")
+	if !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+}
+
+func TestWithWindowDSTGap(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched, err := Parse("0 0,15,30,45 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Start (02:30) falls inside the Mar 11 2012 spring-forward gap
+	// (2:00-3:00 local doesn't exist), which must not make nextOpen
+	// recompute the same non-existent instant forever. It's clamped
+	// forward to the gap's end, 3:00 EDT, the first real instant that day.
+	window := TimeWindow{Start: 2*time.Hour + 30*time.Minute, End: 4 * time.Hour, Weekday: ^uint64(0), Location: loc}
+	filtered := WithWindow(sched, window)
+
+	actual := filtered.Next(getTimeTZ("2012-03-11T00:00:00-0500"))
+	expected := getTimeTZ("2012-03-11T03:00:00-0400")
+	if !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+}